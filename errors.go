@@ -0,0 +1,84 @@
+package opensky
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var (
+	// ErrInvalidICAO24 is returned when an icao24 transponder address is missing or
+	// isn't a 6-character hex string.
+	ErrInvalidICAO24 = errors.New("opensky: invalid icao24 address")
+	// ErrInvalidAirport is returned when an ICAO airport code is missing.
+	ErrInvalidAirport = errors.New("opensky: invalid airport code")
+	// ErrIntervalRequired is returned when a query requires both begin and end times
+	// but one or both were left zero-valued.
+	ErrIntervalRequired = errors.New("opensky: begin and end time are required")
+	// ErrIntervalTooLarge is returned when the [begin, end] interval exceeds what the
+	// endpoint allows.
+	ErrIntervalTooLarge = errors.New("opensky: time interval is too large")
+	// ErrIntervalInverted is returned when end is before begin.
+	ErrIntervalInverted = errors.New("opensky: end time is before begin time")
+	// ErrNotFound is returned when the API responds with 404, meaning no data was
+	// found for the given query.
+	ErrNotFound = errors.New("opensky: no data found")
+	// ErrRateLimited is returned when the request was rejected because it would
+	// exceed the configured rate or credit limit.
+	ErrRateLimited = errors.New("opensky: rate limit exceeded")
+)
+
+var icao24Pattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// APIError is returned for any non-2xx response from the OpenSky API that doesn't map to a
+// more specific sentinel error.
+type APIError struct {
+	Status    int
+	Message   string
+	Path      string
+	Timestamp time.Time
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("opensky: request to %s failed with status %d: %s", e.Path, e.Status, e.Message)
+}
+
+// Unwrap allows errors.Is(err, ErrNotFound) to match a 404 APIError.
+func (e *APIError) Unwrap() error {
+	if e.Status == 404 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// validateICAO24 checks that icao24 is a required 6-character hex transponder address.
+func validateICAO24(icao24 string) error {
+	if !icao24Pattern.MatchString(icao24) {
+		return ErrInvalidICAO24
+	}
+	return nil
+}
+
+// validateAirport checks that an ICAO airport code was provided.
+func validateAirport(airport string) error {
+	if airport == "" {
+		return ErrInvalidAirport
+	}
+	return nil
+}
+
+// validateInterval checks that begin and end are both set, in order, and no further apart
+// than max.
+func validateInterval(begin, end time.Time, max time.Duration) error {
+	if begin.IsZero() || end.IsZero() {
+		return ErrIntervalRequired
+	}
+	if end.Before(begin) {
+		return ErrIntervalInverted
+	}
+	if end.Sub(begin) > max {
+		return ErrIntervalTooLarge
+	}
+	return nil
+}
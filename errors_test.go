@@ -0,0 +1,33 @@
+package opensky
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateInterval(t *testing.T) {
+	begin := time.Unix(100, 0)
+	end := time.Unix(200, 0)
+
+	tests := []struct {
+		name       string
+		begin, end time.Time
+		max        time.Duration
+		wantErr    error
+	}{
+		{"valid", begin, end, time.Hour, nil},
+		{"begin zero", time.Time{}, end, time.Hour, ErrIntervalRequired},
+		{"end zero", begin, time.Time{}, time.Hour, ErrIntervalRequired},
+		{"inverted", end, begin, time.Hour, ErrIntervalInverted},
+		{"too large", begin, end, 50 * time.Second, ErrIntervalTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInterval(tt.begin, tt.end, tt.max)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validateInterval() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package export serializes OpenSky track data into formats mapping tools understand.
+//
+// GetTracksResponse is defined in the root opensky package, so the functions here take it as
+// an argument rather than as a method receiver, since Go doesn't allow attaching methods to a
+// type from another package. A local wrapper type (e.g. `type Track opensky.GetTracksResponse`)
+// would have preserved r.WriteGPX(w)/r.WriteKML(w) call-site ergonomics at the cost of forcing
+// callers to convert into and back out of that wrapper; package-level functions were chosen
+// instead to keep GetTracksResponse usable as-is everywhere else.
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	opensky "github.com/invander/go-opensky-api"
+)
+
+type gpxTrackpoint struct {
+	Lat        float64          `xml:"lat,attr"`
+	Lon        float64          `xml:"lon,attr"`
+	Elevation  float64          `xml:"ele"`
+	Time       string           `xml:"time"`
+	Extensions gpxTrkptExtended `xml:"extensions"`
+}
+
+type gpxTrkptExtended struct {
+	TrueTrack float64 `xml:"true_track"`
+	OnGround  bool    `xml:"on_ground"`
+}
+
+type gpxTrkseg struct {
+	Trackpoints []gpxTrackpoint `xml:"trkpt"`
+}
+
+type gpxTrk struct {
+	Name   string    `xml:"name"`
+	Trkseg gpxTrkseg `xml:"trkseg"`
+}
+
+type gpxDocument struct {
+	XMLName xml.Name `xml:"gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Trk     gpxTrk   `xml:"trk"`
+}
+
+// WriteGPX serializes r as a GPX 1.1 document with a single <trk>/<trkseg>, writing one
+// <trkpt> per waypoint that has a non-nil latitude and longitude. Each point carries its
+// barometric altitude as <ele>, its timestamp as RFC3339, and true_track/on_ground in an
+// <extensions> block.
+func WriteGPX(r opensky.GetTracksResponse, w io.Writer) error {
+	doc := gpxDocument{
+		Version: "1.1",
+		Creator: "go-opensky-api",
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Trk: gpxTrk{
+			Name: fmt.Sprintf("%s %s", r.Icao24, r.Callsign),
+		},
+	}
+
+	for _, wp := range r.Paths {
+		if wp.Latitude == nil || wp.Longitude == nil {
+			continue
+		}
+		var elevation float64
+		if wp.BaroAltitude != nil {
+			elevation = *wp.BaroAltitude
+		}
+		var trueTrack float64
+		if wp.TrueTrack != nil {
+			trueTrack = *wp.TrueTrack
+		}
+		doc.Trk.Trkseg.Trackpoints = append(doc.Trk.Trkseg.Trackpoints, gpxTrackpoint{
+			Lat:       *wp.Latitude,
+			Lon:       *wp.Longitude,
+			Elevation: elevation,
+			Time:      wp.Time.UTC().Format("2006-01-02T15:04:05Z07:00"),
+			Extensions: gpxTrkptExtended{
+				TrueTrack: trueTrack,
+				OnGround:  wp.OnGround,
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	opensky "github.com/invander/go-opensky-api"
+)
+
+func TestWriteGPXWithWaypoints(t *testing.T) {
+	r := opensky.GetTracksResponse{
+		Icao24:   "3c6444",
+		Callsign: "DLH9LF",
+		Paths: []opensky.Waypoint{
+			{
+				Time:         time.Unix(1458564120, 0),
+				Latitude:     floatPtr(50.1964),
+				Longitude:    floatPtr(6.1546),
+				BaroAltitude: floatPtr(9639.3),
+				TrueTrack:    floatPtr(98.26),
+			},
+			{Latitude: nil, Longitude: nil}, // skipped: no position
+		},
+	}
+
+	var buf strings.Builder
+	if err := WriteGPX(r, &buf); err != nil {
+		t.Fatalf("WriteGPX: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "<name>3c6444 DLH9LF</name>") {
+		t.Fatalf("missing name element: %s", out)
+	}
+	if strings.Count(out, "<trkpt") != 1 {
+		t.Fatalf("expected 1 <trkpt>, got: %s", out)
+	}
+	if !strings.Contains(out, `lat="50.1964"`) || !strings.Contains(out, `lon="6.1546"`) {
+		t.Fatalf("missing lat/lon attrs: %s", out)
+	}
+	if !strings.Contains(out, "<ele>9639.3</ele>") {
+		t.Fatalf("missing elevation: %s", out)
+	}
+	if !strings.Contains(out, "<true_track>98.26</true_track>") {
+		t.Fatalf("missing true_track: %s", out)
+	}
+}
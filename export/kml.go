@@ -0,0 +1,80 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	opensky "github.com/invander/go-opensky-api"
+)
+
+type kmlTrack struct {
+	XMLName xml.Name `xml:"gx:Track"`
+	Whens   []string `xml:"when"`
+	Coords  []string `xml:"gx:coord"`
+}
+
+type kmlLineString struct {
+	XMLName xml.Name `xml:"LineString"`
+}
+
+type kmlPlacemark struct {
+	Name       string         `xml:"name"`
+	Track      *kmlTrack      `xml:"gx:Track,omitempty"`
+	LineString *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlDocument struct {
+	Placemark kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlKML struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	XmlnsGx  string      `xml:"xmlns:gx,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+// WriteKML serializes r as a KML document with a single <Placemark> whose <gx:Track>
+// carries one <when>/<gx:coord> pair per waypoint that has a non-nil latitude and longitude,
+// so it renders as an animated path in Google Earth. Waypoints without a position are
+// skipped. If r has no usable waypoints, an empty <LineString> is emitted instead of a track
+// with nothing in it.
+func WriteKML(r opensky.GetTracksResponse, w io.Writer) error {
+	var whens, coords []string
+	for _, wp := range r.Paths {
+		if wp.Latitude == nil || wp.Longitude == nil {
+			continue
+		}
+		var alt float64
+		if wp.BaroAltitude != nil {
+			alt = *wp.BaroAltitude
+		}
+		whens = append(whens, wp.Time.UTC().Format("2006-01-02T15:04:05Z07:00"))
+		coords = append(coords, fmt.Sprintf("%g %g %g", *wp.Longitude, *wp.Latitude, alt))
+	}
+
+	placemark := kmlPlacemark{
+		Name: fmt.Sprintf("%s %s", r.Icao24, r.Callsign),
+	}
+	if len(whens) == 0 {
+		placemark.LineString = &kmlLineString{}
+	} else {
+		placemark.Track = &kmlTrack{Whens: whens, Coords: coords}
+	}
+
+	doc := kmlKML{
+		Xmlns:   "http://www.opengis.net/kml/2.2",
+		XmlnsGx: "http://www.google.com/kml/ext/2.2",
+		Document: kmlDocument{
+			Placemark: placemark,
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	opensky "github.com/invander/go-opensky-api"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestWriteKMLWithWaypoints(t *testing.T) {
+	r := opensky.GetTracksResponse{
+		Icao24:   "3c6444",
+		Callsign: "DLH9LF",
+		Paths: []opensky.Waypoint{
+			{
+				Time:         time.Unix(1458564120, 0),
+				Latitude:     floatPtr(50.1964),
+				Longitude:    floatPtr(6.1546),
+				BaroAltitude: floatPtr(9639.3),
+			},
+			{Latitude: nil, Longitude: nil}, // skipped: no position
+			{
+				Time:      time.Unix(1458564130, 0),
+				Latitude:  floatPtr(50.2),
+				Longitude: floatPtr(6.2),
+			},
+		},
+	}
+
+	var buf strings.Builder
+	if err := WriteKML(r, &buf); err != nil {
+		t.Fatalf("WriteKML: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "<name>3c6444 DLH9LF</name>") {
+		t.Fatalf("missing name element: %s", out)
+	}
+	if strings.Count(out, "<when>") != 2 {
+		t.Fatalf("expected 2 <when> elements, got: %s", out)
+	}
+	if strings.Count(out, "<gx:coord>") != 2 {
+		t.Fatalf("expected 2 <gx:coord> elements, got: %s", out)
+	}
+	if !strings.Contains(out, "<gx:coord>6.1546 50.1964 9639.3</gx:coord>") {
+		t.Fatalf("missing first coord: %s", out)
+	}
+	if strings.Contains(out, "<LineString") {
+		t.Fatalf("unexpected empty LineString: %s", out)
+	}
+}
+
+func TestWriteKMLWithNoWaypoints(t *testing.T) {
+	r := opensky.GetTracksResponse{Icao24: "3c6444", Callsign: "DLH9LF"}
+
+	var buf strings.Builder
+	if err := WriteKML(r, &buf); err != nil {
+		t.Fatalf("WriteKML: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "<LineString") {
+		t.Fatalf("expected empty LineString fallback: %s", out)
+	}
+	if strings.Contains(out, "<gx:Track") {
+		t.Fatalf("unexpected gx:Track with no waypoints: %s", out)
+	}
+}
@@ -64,3 +64,70 @@ type Waypoint struct {
 	TrueTrack    *float64  `json:"true_track"`
 	OnGround     bool      `json:"on_ground"`
 }
+
+// BBox is a geographic bounding box used to scope a states query to a region.
+type BBox struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
+}
+
+// PositionSource identifies which system produced a state vector's position.
+type PositionSource int
+
+const (
+	PositionSourceADSB PositionSource = iota
+	PositionSourceASTERIX
+	PositionSourceMLAT
+	PositionSourceFLARM
+)
+
+// StateOptions controls the scope of a states query.
+type StateOptions struct {
+	// Time retrieves states at a certain point in time. Zero value means "now".
+	Time time.Time
+	// Icao24 restricts the result to one or more transponder addresses.
+	Icao24 []string
+	// BoundingBox restricts the result to states within a geographic area.
+	BoundingBox *BBox
+	// Serials restricts the result to one or more of the caller's own receivers.
+	// Only honored by GetOwnStates.
+	Serials []int
+	// IncludeCategory requests the aircraft category field (the extended=1 flag).
+	IncludeCategory bool
+}
+
+// Unstructured raw response for states queries.
+type unstructuredStatesResponse struct {
+	Time   int64           `json:"time"`
+	States [][]interface{} `json:"states"`
+}
+
+// StatesResponse is the parsed result of a states query.
+type StatesResponse struct {
+	Time   time.Time
+	States []StateVector
+}
+
+// StateVector is the state of a single aircraft at a given point in time.
+type StateVector struct {
+	Icao24         string
+	Callsign       *string
+	OriginCountry  string
+	TimePosition   *time.Time
+	LastContact    time.Time
+	Longitude      *float64
+	Latitude       *float64
+	BaroAltitude   *float64
+	OnGround       bool
+	Velocity       *float64
+	TrueTrack      *float64
+	VerticalRate   *float64
+	Sensors        []int
+	GeoAltitude    *float64
+	SquawkCode     *string
+	Spi            bool
+	PositionSource PositionSource
+	Category       *int
+}
@@ -1,10 +1,13 @@
 package opensky
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -17,28 +20,84 @@ type Client struct {
 	username   string
 	password   string
 	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+
+	retryPolicy  RetryPolicy
+	requestHook  func(*http.Request)
+	responseHook func(*http.Response, error)
+
+	rateLimiter RateLimiter
+
+	rlMu          sync.Mutex
+	lastRateLimit *RateLimitInfo
 }
 
 // NewClient Creates a new OpenSky client.
-// Username and password fields are optional.
-func NewClient(username string, password string) *Client {
-	return &Client{
+// Username and password fields are optional. Pass ClientOptions to configure rate limiting
+// and other optional behavior.
+//
+// NewClient's methods take a context.Context and validate their arguments before sending a
+// request (rejecting, for example, a malformed icao24 or an out-of-order time interval). This
+// is a breaking change from the pre-context API: callers upgrading from a version of this
+// module without context.Context parameters must update every call site, and may start seeing
+// validation errors for inputs the old client accepted and forwarded straight to the API. There
+// is no compatibility constructor; this is a v2 of the client, and should be versioned as such
+// (e.g. a /v2 module path) rather than depended on alongside the old API.
+func NewClient(username string, password string, opts ...ClientOption) *Client {
+	c := &Client{
 		username: username,
 		password: password,
 		httpClient: &http.Client{
 			Timeout: time.Minute * 5,
 		},
+		baseURL:     baseOpenSkyURL,
+		retryPolicy: defaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RemainingCredits returns the number of credits left in the client's configured daily
+// budget, or -1 if no daily budget was configured via WithDailyCredits.
+func (c *Client) RemainingCredits() int {
+	if c.rateLimiter == nil {
+		return -1
+	}
+	return c.rateLimiter.Remaining()
+}
+
+// LastRateLimit returns the rate-limit information parsed from the most recent response's
+// headers, or nil if the API didn't report any.
+func (c *Client) LastRateLimit() *RateLimitInfo {
+	c.rlMu.Lock()
+	defer c.rlMu.Unlock()
+	return c.lastRateLimit
+}
+
+// endpointURL joins the client's base URL with the given path segments, e.g.
+// c.endpointURL("flights", "all").
+func (c *Client) endpointURL(segments ...string) (string, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
 	}
+	return base.JoinPath(segments...).String(), nil
 }
 
 // Creates a new HTTP request, with the basic authentication header already set.
-func (c *Client) newRequest(method string, apiURL string) (request *http.Request, err error) {
-	request, err = http.NewRequest(method, apiURL, nil)
+func (c *Client) newRequest(ctx context.Context, method string, apiURL string) (request *http.Request, err error) {
+	request, err = http.NewRequestWithContext(ctx, method, apiURL, nil)
 	if err != nil {
 		return
 	}
 
 	request.Header.Set("Accept", "application/json; charset=utf-8")
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
 
 	if request != nil && c.username != "" && c.password != "" {
 		request.SetBasicAuth(c.username, c.password)
@@ -46,27 +105,80 @@ func (c *Client) newRequest(method string, apiURL string) (request *http.Request
 	return
 }
 
+// flatRequestCost is the credit cost charged for endpoints that, unlike /states/all and
+// /states/own, don't have a documented area/time-based cost.
+const flatRequestCost = 1
+
 // doHTTP is a utility method for performing an HTTP request and parsing the
 // JSON response inside the passed responseObject.
 //
+// cost is the number of credits this request consumes, checked against the client's
+// RateLimiter, if one was configured via WithDailyCredits/WithRequestsPerMinute/WithRateLimiter.
+//
 // If the operation fails for any reason, an error is returned.
-// If the HTTP request returns any status code other than 200, an error is returned.
-func (c *Client) doHTTP(request *http.Request, responseObject interface{}) (err error) {
+// If the HTTP request returns any status code other than 200, an *APIError is returned,
+// which Unwraps to ErrNotFound for a 404 response. Responses whose status is listed in the
+// client's RetryPolicy are retried, honoring the Retry-After header when present and falling
+// back to exponential backoff with jitter otherwise.
+func (c *Client) doHTTP(request *http.Request, cost int, responseObject interface{}) (err error) {
+	if c.rateLimiter != nil {
+		if err = c.rateLimiter.Reserve(request.Context(), cost); err != nil {
+			return
+		}
+	}
+
 	var resp *http.Response
-	resp, err = c.httpClient.Do(request)
-	if err != nil {
-		return
+	for attempt := 0; ; attempt++ {
+		if c.requestHook != nil {
+			c.requestHook(request)
+		}
+		resp, err = c.httpClient.Do(request)
+		if c.responseHook != nil {
+			c.responseHook(resp, err)
+		}
+		if err != nil {
+			return
+		}
+
+		if c.retryPolicy.retryable(resp.StatusCode) && attempt < c.retryPolicy.MaxAttempts-1 {
+			wait := retryAfter(resp.Header)
+			if wait <= 0 {
+				wait = backoffWithJitter(attempt, c.retryPolicy.BaseDelay)
+			}
+			resp.Body.Close()
+			select {
+			case <-time.After(wait):
+				continue
+			case <-request.Context().Done():
+				return request.Context().Err()
+			}
+		}
+		break
 	}
-	// Parse response
 	defer resp.Body.Close()
 
+	if info := parseRateLimitInfo(resp.Header); info != nil {
+		c.rlMu.Lock()
+		c.lastRateLimit = info
+		c.rlMu.Unlock()
+	}
+
 	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			return ErrRateLimited
+		}
+		apiErr := &APIError{Status: resp.StatusCode, Path: request.URL.Path}
 		var errRes errorResponse
-		if err = json.NewDecoder(resp.Body).Decode(&errRes); err == nil {
-			return errors.New(errRes.Message)
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&errRes); decodeErr == nil {
+			apiErr.Message = errRes.Message
+			apiErr.Path = errRes.Path
+			if errRes.Timestamp != 0 {
+				apiErr.Timestamp = time.Unix(errRes.Timestamp/1000, 0)
+			}
+		} else {
+			apiErr.Message = fmt.Sprintf("unknown error, status code: %d", resp.StatusCode)
 		}
-
-		return fmt.Errorf("unknown error, status code: %d", resp.StatusCode)
+		return apiErr
 	}
 
 	if err = json.NewDecoder(resp.Body).Decode(&responseObject); err != nil {
@@ -76,26 +188,54 @@ func (c *Client) doHTTP(request *http.Request, responseObject interface{}) (err
 	return nil
 }
 
+// retryAfter parses the Retry-After header, which OpenSky sends as a number of seconds.
+// It returns 0 if the header is absent or invalid.
+func retryAfter(header http.Header) time.Duration {
+	raw := header.Get("Retry-After")
+	if raw == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// maxAllFlightsInterval is the largest [begin, end] interval the /flights/all endpoint accepts,
+// per the OpenSky API documentation.
+const maxAllFlightsInterval = 2 * time.Hour
+
+// maxFlightsInterval is the largest [begin, end] interval the /flights/aircraft endpoint
+// accepts, per the OpenSky API documentation.
+const maxFlightsInterval = 30 * 24 * time.Hour
+
+// maxAirportInterval is the largest [begin, end] interval the airport arrival/departure
+// endpoints accept.
+const maxAirportInterval = 7 * 24 * time.Hour
+
 // GetFlights retrieves all flight information within a certain time interval.
 // Flights departed and arrived within the [begin, end] boundaries will be returned.
 //
-// If no flights were found for the given time period, a 404 error will be returned instead.
-func (c *Client) GetFlights(begin time.Time, end time.Time) (flights []Flight, err error) {
-	request, err := c.newRequest("GET", fmt.Sprintf("%s/flights/all", baseOpenSkyURL))
-	if err != nil {
+// If no flights were found for the given time period, an error wrapping ErrNotFound is returned.
+func (c *Client) GetFlights(ctx context.Context, begin time.Time, end time.Time) (flights []Flight, err error) {
+	if err = validateInterval(begin, end, maxAllFlightsInterval); err != nil {
 		return
 	}
-	q := request.URL.Query()
-	// Add optional parameters
-	if !begin.IsZero() {
-		q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	apiURL, err := c.endpointURL("flights", "all")
+	if err != nil {
+		return
 	}
-	if !end.IsZero() {
-		q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
 	}
+	q := request.URL.Query()
+	q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	q.Set("end", fmt.Sprintf("%v", end.Unix()))
 	request.URL.RawQuery = q.Encode()
 	// Fetch response
-	err = c.doHTTP(request, &flights)
+	err = c.doHTTP(request, flatRequestCost, &flights)
 	return
 }
 
@@ -103,126 +243,141 @@ func (c *Client) GetFlights(begin time.Time, end time.Time) (flights []Flight, e
 // within a certain time interval.
 // Flights departed and arrived within the [begin, end] boundaries will be returned.
 //
-// If no flights were found for the given time period, a 404 error will be returned instead.
-func (c *Client) GetFlightsByAircraft(icao24 string, begin time.Time, end time.Time) (flights []Flight, err error) {
-	request, err := c.newRequest("GET", fmt.Sprintf("%s/flights/aircraft", baseOpenSkyURL))
-	if err != nil {
+// If no flights were found for the given time period, an error wrapping ErrNotFound is returned.
+func (c *Client) GetFlightsByAircraft(ctx context.Context, icao24 string, begin time.Time, end time.Time) (flights []Flight, err error) {
+	if err = validateICAO24(icao24); err != nil {
 		return
 	}
-	q := request.URL.Query()
-	// Add optional parameters
-	if !begin.IsZero() {
-		q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	if err = validateInterval(begin, end, maxFlightsInterval); err != nil {
+		return
 	}
-	if !end.IsZero() {
-		q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	apiURL, err := c.endpointURL("flights", "aircraft")
+	if err != nil {
+		return
 	}
-	if icao24 != "" {
-		q.Set("icao24", icao24)
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
 	}
+	q := request.URL.Query()
+	q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	q.Set("icao24", icao24)
 	request.URL.RawQuery = q.Encode()
 	// Fetch response
-	err = c.doHTTP(request, &flights)
+	err = c.doHTTP(request, flatRequestCost, &flights)
 	return
 }
 
 // GetFlightsByInterval retrieves flights for a certain time interval [begin, end].
 //
-// If no flights were found for the given time period, a 404 error will be returned instead.
-func (c *Client) GetFlightsByInterval(begin time.Time, end time.Time) (flights []Flight, err error) {
-	request, err := c.newRequest("GET", fmt.Sprintf("%s/flights/all", baseOpenSkyURL))
-	if err != nil {
+// If no flights were found for the given time period, an error wrapping ErrNotFound is returned.
+func (c *Client) GetFlightsByInterval(ctx context.Context, begin time.Time, end time.Time) (flights []Flight, err error) {
+	if err = validateInterval(begin, end, maxAllFlightsInterval); err != nil {
 		return
 	}
-	q := request.URL.Query()
-	// Add optional parameters
-	if !begin.IsZero() {
-		q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	apiURL, err := c.endpointURL("flights", "all")
+	if err != nil {
+		return
 	}
-	if !end.IsZero() {
-		q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
 	}
+	q := request.URL.Query()
+	q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	q.Set("end", fmt.Sprintf("%v", end.Unix()))
 
 	request.URL.RawQuery = q.Encode()
 	// Fetch response
-	err = c.doHTTP(request, &flights)
+	err = c.doHTTP(request, flatRequestCost, &flights)
 	return
 }
 
 // GetFlightsByArrival retrieve flights for a certain airport which arrived within a given time interval [begin, end].
 //
-// If no flights were found for the given time period, a 404 error will be returned instead.
-func (c *Client) GetFlightsByArrival(airport string, begin time.Time, end time.Time) (flights []Flight, err error) {
-	request, err := c.newRequest("GET", fmt.Sprintf("%s/flights/arrival", baseOpenSkyURL))
-	if err != nil {
+// If no flights were found for the given time period, an error wrapping ErrNotFound is returned.
+func (c *Client) GetFlightsByArrival(ctx context.Context, airport string, begin time.Time, end time.Time) (flights []Flight, err error) {
+	if err = validateAirport(airport); err != nil {
 		return
 	}
-	q := request.URL.Query()
-	// Add optional parameters
-	if !begin.IsZero() {
-		q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	if err = validateInterval(begin, end, maxAirportInterval); err != nil {
+		return
 	}
-	if !end.IsZero() {
-		q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	apiURL, err := c.endpointURL("flights", "arrival")
+	if err != nil {
+		return
 	}
-	if airport != "" {
-		q.Set("airport", airport)
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
 	}
+	q := request.URL.Query()
+	q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	q.Set("airport", airport)
 
 	request.URL.RawQuery = q.Encode()
 	// Fetch response
-	err = c.doHTTP(request, &flights)
+	err = c.doHTTP(request, flatRequestCost, &flights)
 	return
 }
 
 // GetFlightsByDeparture retrieve flights for a certain airport which departed within a given time interval [begin, end].
 //
-// If no flights were found for the given time period, a 404 error will be returned instead.
-func (c *Client) GetFlightsByDeparture(airport string, begin time.Time, end time.Time) (flights []Flight, err error) {
-	request, err := c.newRequest("GET", fmt.Sprintf("%s/flights/departure", baseOpenSkyURL))
-	if err != nil {
+// If no flights were found for the given time period, an error wrapping ErrNotFound is returned.
+func (c *Client) GetFlightsByDeparture(ctx context.Context, airport string, begin time.Time, end time.Time) (flights []Flight, err error) {
+	if err = validateAirport(airport); err != nil {
 		return
 	}
-	q := request.URL.Query()
-	// Add optional parameters
-	if !begin.IsZero() {
-		q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	if err = validateInterval(begin, end, maxAirportInterval); err != nil {
+		return
 	}
-	if !end.IsZero() {
-		q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	apiURL, err := c.endpointURL("flights", "departure")
+	if err != nil {
+		return
 	}
-	if airport != "" {
-		q.Set("airport", airport)
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
 	}
+	q := request.URL.Query()
+	q.Set("begin", fmt.Sprintf("%v", begin.Unix()))
+	q.Set("end", fmt.Sprintf("%v", end.Unix()))
+	q.Set("airport", airport)
 
 	request.URL.RawQuery = q.Encode()
 	// Fetch response
-	err = c.doHTTP(request, &flights)
+	err = c.doHTTP(request, flatRequestCost, &flights)
 	return
 }
 
 // GetTrackByAircraft Retrieve the trajectory for a certain aircraft at a given time.
 // The trajectory is a list of waypoints containing position, barometric altitude, true track and an on-ground flag.
 //
-// If no flights were found for the given time period, a 404 error will be returned instead.
-func (c *Client) GetTrackByAircraft(icao24 string, time time.Time) (response GetTracksResponse, err error) {
-	request, err := c.newRequest("GET", fmt.Sprintf("%s/tracks/all", baseOpenSkyURL))
+// If no track was found for the given time, an error wrapping ErrNotFound is returned.
+func (c *Client) GetTrackByAircraft(ctx context.Context, icao24 string, trackTime time.Time) (response GetTracksResponse, err error) {
+	if err = validateICAO24(icao24); err != nil {
+		return
+	}
+	apiURL, err := c.endpointURL("tracks", "all")
 	if err != nil {
 		return
 	}
-	q := request.URL.Query()
-	// Add optional parameters
-	if icao24 != "" {
-		q.Set("icao24", icao24)
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
 	}
-	if !time.IsZero() {
-		q.Set("time", fmt.Sprintf("%v", time.Unix()))
+	q := request.URL.Query()
+	q.Set("icao24", icao24)
+	if !trackTime.IsZero() {
+		q.Set("time", fmt.Sprintf("%v", trackTime.Unix()))
 	}
 
 	request.URL.RawQuery = q.Encode()
 	// Fetch response
 	var rawResponse unstructuredTrackResponse
-	err = c.doHTTP(request, &rawResponse)
+	err = c.doHTTP(request, flatRequestCost, &rawResponse)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,72 @@
+package opensky
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoHTTPRetriesRetryableStatusThenSucceeds(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]Flight{{Icao24: "abc123"}})
+	}))
+	defer server.Close()
+
+	client := NewClient("", "", WithBaseURL(server.URL))
+	flights, err := client.GetFlights(context.Background(), time.Unix(1, 0), time.Unix(2, 0))
+	if err != nil {
+		t.Fatalf("GetFlights: %v", err)
+	}
+	if len(flights) != 1 || flights[0].Icao24 != "abc123" {
+		t.Fatalf("unexpected flights: %+v", flights)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("got %d calls, want 2 (one failure, one retry)", got)
+	}
+}
+
+// TestRateLimiterAppliesToEveryEndpoint guards against the rate limiter only being consulted
+// by the states endpoints: every public Client method must reserve credits through doHTTP.
+func TestRateLimiterAppliesToEveryEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Flight{})
+	}))
+	defer server.Close()
+
+	client := NewClient("", "", WithBaseURL(server.URL))
+	// Swap in a limiter that always rejects, to prove every endpoint actually calls Reserve.
+	client.rateLimiter = rejectingLimiter{}
+
+	_, err := client.GetFlights(context.Background(), time.Unix(1, 0), time.Unix(2, 0))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GetFlights: got %v, want ErrRateLimited", err)
+	}
+
+	_, err = client.GetFlightsByAircraft(context.Background(), "abc123", time.Unix(1, 0), time.Unix(2, 0))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GetFlightsByAircraft: got %v, want ErrRateLimited", err)
+	}
+
+	_, err = client.GetTrackByAircraft(context.Background(), "abc123", time.Time{})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GetTrackByAircraft: got %v, want ErrRateLimited", err)
+	}
+}
+
+// rejectingLimiter is a RateLimiter test double that always refuses, to prove doHTTP actually
+// consults whatever RateLimiter the Client is configured with.
+type rejectingLimiter struct{}
+
+func (rejectingLimiter) Reserve(ctx context.Context, cost int) error { return ErrRateLimited }
+func (rejectingLimiter) Remaining() int                              { return 0 }
@@ -0,0 +1,94 @@
+package opensky
+
+import "net/http"
+
+// ClientOption configures optional Client behavior. Pass zero or more to NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient installs a custom *http.Client, e.g. to inject a custom RoundTripper for
+// tracing or metrics. Overrides the default client, which has a 5 minute timeout.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBaseURL overrides the OpenSky API base URL. This is mainly useful for pointing the
+// client at an httptest.Server in tests.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetry overrides the client's RetryPolicy, which by default retries 429/502/503/504
+// responses up to twice with exponential backoff and jitter.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRequestHook installs a callback invoked with every outgoing *http.Request, including
+// retries, before it is sent. Useful for logging or metrics.
+func WithRequestHook(hook func(*http.Request)) ClientOption {
+	return func(c *Client) {
+		c.requestHook = hook
+	}
+}
+
+// WithResponseHook installs a callback invoked after every attempt completes, with the
+// resulting *http.Response (nil on transport error) and the transport error, if any.
+func WithResponseHook(hook func(*http.Response, error)) ClientOption {
+	return func(c *Client) {
+		c.responseHook = hook
+	}
+}
+
+// WithDailyCredits configures the client to enforce a daily credit budget of n, matching
+// the budget OpenSky assigns to anonymous, registered, or contributing accounts. Requests
+// that would exceed the budget return ErrRateLimited instead of being sent.
+//
+// WithDailyCredits and WithRequestsPerMinute share the same RateLimiter; calling both
+// combines their budgets.
+func WithDailyCredits(n int) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = combineRateLimiterBudget(c.rateLimiter, n, 0)
+	}
+}
+
+// WithRequestsPerMinute configures the client to throttle to at most n requests per minute.
+func WithRequestsPerMinute(n int) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = combineRateLimiterBudget(c.rateLimiter, 0, n)
+	}
+}
+
+// WithRateLimiter installs a custom RateLimiter, overriding WithDailyCredits and
+// WithRequestsPerMinute.
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// combineRateLimiterBudget folds a daily-credits or requests-per-minute budget into the
+// existing limiter, preserving whichever budget was already configured.
+func combineRateLimiterBudget(existing RateLimiter, dailyCredits, requestsPerMinute int) RateLimiter {
+	if tb, ok := existing.(*tokenBucketLimiter); ok {
+		if dailyCredits == 0 {
+			dailyCredits = tb.dailyCredits
+		}
+		if requestsPerMinute == 0 {
+			requestsPerMinute = tb.requestsPerMinute
+		}
+	}
+	return NewTokenBucketLimiter(dailyCredits, requestsPerMinute)
+}
@@ -0,0 +1,184 @@
+package opensky
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides whether a request is allowed to proceed, based on however the
+// implementation chooses to budget credits.
+type RateLimiter interface {
+	// Reserve blocks until cost credits are available, or returns ErrRateLimited if the
+	// daily budget is exhausted. It returns ctx.Err() if ctx is cancelled while waiting.
+	Reserve(ctx context.Context, cost int) error
+	// Remaining returns the number of credits left in the current daily budget, or -1 if
+	// the limiter doesn't track a daily budget.
+	Remaining() int
+}
+
+// tokenBucketLimiter is the default RateLimiter. It tracks a daily credit budget that resets
+// every 24 hours, and optionally throttles to a maximum number of requests per minute.
+type tokenBucketLimiter struct {
+	mu sync.Mutex
+
+	dailyCredits int
+	usedToday    int
+	dayReset     time.Time
+
+	requestsPerMinute int
+	minuteWindowStart time.Time
+	usedThisMinute    int
+
+	// now and after are swapped out by tests with a fake clock; they default to time.Now
+	// and time.After.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// NewTokenBucketLimiter creates a RateLimiter enforcing dailyCredits credits per rolling
+// 24-hour window and requestsPerMinute requests per rolling 1-minute window. A value of 0
+// for either disables that particular budget.
+func NewTokenBucketLimiter(dailyCredits int, requestsPerMinute int) RateLimiter {
+	now := time.Now()
+	return &tokenBucketLimiter{
+		dailyCredits:      dailyCredits,
+		dayReset:          now.Add(24 * time.Hour),
+		requestsPerMinute: requestsPerMinute,
+		minuteWindowStart: now,
+		now:               time.Now,
+		after:             time.After,
+	}
+}
+
+func (l *tokenBucketLimiter) Reserve(ctx context.Context, cost int) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		if !now.Before(l.dayReset) {
+			l.usedToday = 0
+			l.dayReset = now.Add(24 * time.Hour)
+		}
+		if l.dailyCredits > 0 && l.usedToday+cost > l.dailyCredits {
+			l.mu.Unlock()
+			return ErrRateLimited
+		}
+
+		if l.requestsPerMinute > 0 {
+			if cost > l.requestsPerMinute {
+				l.mu.Unlock()
+				return ErrRateLimited
+			}
+			if !now.Before(l.minuteWindowStart.Add(time.Minute)) {
+				l.usedThisMinute = 0
+				l.minuteWindowStart = now
+			}
+			if l.usedThisMinute+cost > l.requestsPerMinute {
+				wait := l.minuteWindowStart.Add(time.Minute).Sub(now)
+				l.mu.Unlock()
+				select {
+				case <-l.after(wait):
+					continue
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			l.usedThisMinute += cost
+		}
+
+		l.usedToday += cost
+		l.mu.Unlock()
+		return nil
+	}
+}
+
+func (l *tokenBucketLimiter) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dailyCredits <= 0 {
+		return -1
+	}
+	remaining := l.dailyCredits - l.usedToday
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+// RateLimitInfo mirrors the rate-limit headers OpenSky attaches to a response, when present.
+type RateLimitInfo struct {
+	// Remaining is the value of the X-Rate-Limit-Remaining header.
+	Remaining int
+	// Retries is the value of the X-Rate-Limit-Retries header, counting retries used for
+	// this request due to earlier rate limiting.
+	Retries int
+}
+
+// parseRateLimitInfo extracts rate-limit headers from a response, if present.
+func parseRateLimitInfo(header http.Header) *RateLimitInfo {
+	remainingHeader := header.Get("X-Rate-Limit-Remaining")
+	if remainingHeader == "" {
+		return nil
+	}
+	remaining, err := strconv.Atoi(remainingHeader)
+	if err != nil {
+		return nil
+	}
+	info := &RateLimitInfo{Remaining: remaining}
+	if retries, err := strconv.Atoi(header.Get("X-Rate-Limit-Retries")); err == nil {
+		info.Retries = retries
+	}
+	return info
+}
+
+// stateRequestCost approximates the credit cost OpenSky charges for a /states/all or
+// /states/own query, based on the published cost table: cost scales with the queried area,
+// and historical (more than an hour old) queries cost double.
+func stateRequestCost(opts StateOptions) int {
+	var cost int
+	switch {
+	case opts.BoundingBox == nil:
+		cost = 4
+	default:
+		area := (opts.BoundingBox.MaxLat - opts.BoundingBox.MinLat) * (opts.BoundingBox.MaxLon - opts.BoundingBox.MinLon)
+		switch {
+		case area <= 2:
+			cost = 1
+		case area <= 25:
+			cost = 2
+		case area <= 400:
+			cost = 3
+		default:
+			cost = 4
+		}
+	}
+	if !opts.Time.IsZero() && time.Since(opts.Time) > time.Hour {
+		cost *= 2
+	}
+	return cost
+}
+
+// maxBackoffShift caps the exponent used by backoffWithJitter so base<<attempt can't overflow
+// into a zero or negative duration for a large attempt number.
+const maxBackoffShift = 20
+
+// maxBackoff caps the computed backoff itself, since a large caller-configured BaseDelay can
+// still overflow (or produce an unreasonably long wait) even with maxBackoffShift in place.
+const maxBackoff = 5 * time.Minute
+
+// backoffWithJitter computes an exponential backoff duration for the given attempt number
+// (0-indexed), with up to 50% random jitter added to avoid thundering-herd retries.
+func backoffWithJitter(attempt int, base time.Duration) time.Duration {
+	if attempt > maxBackoffShift {
+		attempt = maxBackoffShift
+	}
+	backoff := base << attempt
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff + jitter
+}
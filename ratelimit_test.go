@@ -0,0 +1,161 @@
+package opensky
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock provides a now/after pair for deterministic rate-limiter tests: after() doesn't
+// actually sleep, it just advances the fake clock by d and fires immediately.
+type fakeClock struct {
+	cur time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.cur
+}
+
+func (f *fakeClock) afterFunc(d time.Duration) <-chan time.Time {
+	f.cur = f.cur.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- f.cur
+	return ch
+}
+
+func newFakeLimiter(dailyCredits, requestsPerMinute int, start time.Time) (*tokenBucketLimiter, *fakeClock) {
+	clock := &fakeClock{cur: start}
+	return &tokenBucketLimiter{
+		dailyCredits:      dailyCredits,
+		dayReset:          start.Add(24 * time.Hour),
+		requestsPerMinute: requestsPerMinute,
+		minuteWindowStart: start,
+		now:               clock.now,
+		after:             clock.afterFunc,
+	}, clock
+}
+
+func TestTokenBucketLimiterDailyCreditsExhausted(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, _ := newFakeLimiter(5, 0, start)
+
+	if err := limiter.Reserve(context.Background(), 5); err != nil {
+		t.Fatalf("Reserve within budget: %v", err)
+	}
+	if remaining := limiter.Remaining(); remaining != 0 {
+		t.Fatalf("Remaining() = %d, want 0", remaining)
+	}
+	if err := limiter.Reserve(context.Background(), 1); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Reserve over budget: got %v, want ErrRateLimited", err)
+	}
+}
+
+func TestTokenBucketLimiterDailyBudgetResetsAfterWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, clock := newFakeLimiter(1, 0, start)
+
+	if err := limiter.Reserve(context.Background(), 1); err != nil {
+		t.Fatalf("Reserve within budget: %v", err)
+	}
+	if err := limiter.Reserve(context.Background(), 1); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Reserve over budget: got %v, want ErrRateLimited", err)
+	}
+
+	clock.cur = clock.cur.Add(24*time.Hour + time.Second)
+
+	if err := limiter.Reserve(context.Background(), 1); err != nil {
+		t.Fatalf("Reserve after daily reset: %v", err)
+	}
+}
+
+func TestTokenBucketLimiterRequestsPerMinuteWaitsOutTheWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, clock := newFakeLimiter(0, 1, start)
+
+	if err := limiter.Reserve(context.Background(), 1); err != nil {
+		t.Fatalf("first Reserve: %v", err)
+	}
+
+	before := clock.cur
+	if err := limiter.Reserve(context.Background(), 1); err != nil {
+		t.Fatalf("second Reserve: %v", err)
+	}
+	if !clock.cur.After(before) {
+		t.Fatal("expected Reserve to advance the clock by waiting out the per-minute window")
+	}
+}
+
+func TestTokenBucketLimiterRejectsCostAboveRequestsPerMinute(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, _ := newFakeLimiter(0, 1, start)
+
+	if err := limiter.Reserve(context.Background(), 4); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Reserve with cost > requestsPerMinute: got %v, want ErrRateLimited", err)
+	}
+}
+
+func TestTokenBucketLimiterRespectsContextCancellation(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, _ := newFakeLimiter(0, 1, start)
+	limiter.after = func(time.Duration) <-chan time.Time {
+		return make(chan time.Time) // never fires
+	}
+
+	if err := limiter.Reserve(context.Background(), 1); err != nil {
+		t.Fatalf("first Reserve: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Reserve(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Reserve with cancelled context: got %v, want context.Canceled", err)
+	}
+}
+
+func TestBackoffWithJitterGrowsExponentiallyWithJitter(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 4; attempt++ {
+		backoff := base << attempt
+		d := backoffWithJitter(attempt, base)
+		if d < backoff || d > backoff+backoff/2 {
+			t.Fatalf("attempt %d: backoffWithJitter = %v, want within [%v, %v]", attempt, d, backoff, backoff+backoff/2)
+		}
+	}
+}
+
+func TestBackoffWithJitterDoesNotOverflowForLargeAttempts(t *testing.T) {
+	for _, attempt := range []int{20, 64, 1000} {
+		if d := backoffWithJitter(attempt, 200*time.Millisecond); d <= 0 {
+			t.Fatalf("attempt %d: backoffWithJitter = %v, want > 0", attempt, d)
+		}
+	}
+}
+
+func TestBackoffWithJitterDoesNotOverflowForLargeBaseDelay(t *testing.T) {
+	if d := backoffWithJitter(maxBackoffShift, 3*time.Hour); d <= 0 {
+		t.Fatalf("backoffWithJitter with a large BaseDelay = %v, want > 0", d)
+	}
+}
+
+func TestStateRequestCost(t *testing.T) {
+	tests := []struct {
+		name string
+		opts StateOptions
+		want int
+	}{
+		{"whole world", StateOptions{}, 4},
+		{"small box", StateOptions{BoundingBox: &BBox{MinLat: 0, MaxLat: 1, MinLon: 0, MaxLon: 1}}, 1},
+		{"medium box", StateOptions{BoundingBox: &BBox{MinLat: 0, MaxLat: 4, MinLon: 0, MaxLon: 5}}, 2},
+		{"large box", StateOptions{BoundingBox: &BBox{MinLat: 0, MaxLat: 18, MinLon: 0, MaxLon: 18}}, 3},
+		{"huge box", StateOptions{BoundingBox: &BBox{MinLat: 0, MaxLat: 90, MinLon: 0, MaxLon: 90}}, 4},
+		{"historical small box", StateOptions{BoundingBox: &BBox{MinLat: 0, MaxLat: 1, MinLon: 0, MaxLon: 1}, Time: time.Now().Add(-2 * time.Hour)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stateRequestCost(tt.opts); got != tt.want {
+				t.Fatalf("stateRequestCost() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
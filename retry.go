@@ -0,0 +1,32 @@
+package opensky
+
+import "time"
+
+// RetryPolicy controls how doHTTP retries a failed request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including the first
+	// attempt. A value <= 1 disables retrying.
+	MaxAttempts int
+	// RetryableStatus lists the HTTP status codes that should be retried.
+	RetryableStatus []int
+	// BaseDelay is the base duration used to compute exponential backoff between
+	// attempts, when the response doesn't carry a Retry-After header.
+	BaseDelay time.Duration
+}
+
+// defaultRetryPolicy retries 429, 502, 503, and 504 responses up to twice, honoring
+// Retry-After when present and otherwise backing off exponentially with jitter.
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	RetryableStatus: []int{429, 502, 503, 504},
+	BaseDelay:       200 * time.Millisecond,
+}
+
+func (p RetryPolicy) retryable(status int) bool {
+	for _, s := range p.RetryableStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
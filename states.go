@@ -0,0 +1,255 @@
+package opensky
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Index constants for the raw state vector array returned by the
+// /states/all and /states/own endpoints.
+const (
+	stateIdxIcao24         = 0
+	stateIdxCallsign       = 1
+	stateIdxOriginCountry  = 2
+	stateIdxTimePosition   = 3
+	stateIdxLastContact    = 4
+	stateIdxLongitude      = 5
+	stateIdxLatitude       = 6
+	stateIdxBaroAltitude   = 7
+	stateIdxOnGround       = 8
+	stateIdxVelocity       = 9
+	stateIdxTrueTrack      = 10
+	stateIdxVerticalRate   = 11
+	stateIdxSensors        = 12
+	stateIdxGeoAltitude    = 13
+	stateIdxSquawk         = 14
+	stateIdxSpi            = 15
+	stateIdxPositionSource = 16
+	stateIdxCategory       = 17
+
+	stateVectorLen         = 17
+	stateVectorExtendedLen = 18
+)
+
+// GetStates retrieves state vectors for all aircraft currently tracked by the network.
+//
+// If no bounding box or icao24 filter is given, the request covers the whole world, which
+// requires a registered account.
+func (c *Client) GetStates(ctx context.Context, opts StateOptions) (response StatesResponse, err error) {
+	apiURL, err := c.endpointURL("states", "all")
+	if err != nil {
+		return
+	}
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
+	}
+	q := request.URL.Query()
+	applyStateOptions(q, opts)
+	request.URL.RawQuery = q.Encode()
+
+	var rawResponse unstructuredStatesResponse
+	err = c.doHTTP(request, stateRequestCost(opts), &rawResponse)
+	if err != nil {
+		return
+	}
+	return parseStatesResponse(rawResponse, opts.IncludeCategory)
+}
+
+// GetOwnStates retrieves state vectors for aircraft received by the authenticated user's own
+// sensors. This requires a registered, feeding account, and supports the Serials option to
+// restrict the result to specific receivers.
+func (c *Client) GetOwnStates(ctx context.Context, opts StateOptions) (response StatesResponse, err error) {
+	apiURL, err := c.endpointURL("states", "own")
+	if err != nil {
+		return
+	}
+	request, err := c.newRequest(ctx, "GET", apiURL)
+	if err != nil {
+		return
+	}
+	q := request.URL.Query()
+	applyStateOptions(q, opts)
+	for _, serial := range opts.Serials {
+		q.Add("serials", strconv.Itoa(serial))
+	}
+	request.URL.RawQuery = q.Encode()
+
+	var rawResponse unstructuredStatesResponse
+	err = c.doHTTP(request, stateRequestCost(opts), &rawResponse)
+	if err != nil {
+		return
+	}
+	return parseStatesResponse(rawResponse, opts.IncludeCategory)
+}
+
+// applyStateOptions sets the query parameters shared by /states/all and /states/own.
+func applyStateOptions(q url.Values, opts StateOptions) {
+	if !opts.Time.IsZero() {
+		q.Set("time", fmt.Sprintf("%v", opts.Time.Unix()))
+	}
+	for _, icao24 := range opts.Icao24 {
+		q.Add("icao24", icao24)
+	}
+	if opts.BoundingBox != nil {
+		q.Set("lamin", fmt.Sprintf("%v", opts.BoundingBox.MinLat))
+		q.Set("lamax", fmt.Sprintf("%v", opts.BoundingBox.MaxLat))
+		q.Set("lomin", fmt.Sprintf("%v", opts.BoundingBox.MinLon))
+		q.Set("lomax", fmt.Sprintf("%v", opts.BoundingBox.MaxLon))
+	}
+	if opts.IncludeCategory {
+		q.Set("extended", "1")
+	}
+}
+
+// Parses an unstructured states response.
+func parseStatesResponse(rawResponse unstructuredStatesResponse, extended bool) (response StatesResponse, err error) {
+	response.Time = time.Unix(rawResponse.Time, 0)
+	for i, s := range rawResponse.States {
+		var state StateVector
+		state, err = decodeState(s, extended)
+		if err != nil {
+			err = fmt.Errorf("invalid state vector at position %d: %w", i, err)
+			return
+		}
+		response.States = append(response.States, state)
+	}
+	return
+}
+
+// decodeState parses a single raw state vector array, as returned within the "states" field of
+// a /states/all or /states/own response, into a StateVector.
+//
+// extended indicates whether the request was made with extended=1, in which case the array
+// carries an 18th element holding the aircraft category.
+func decodeState(raw []interface{}, extended bool) (state StateVector, err error) {
+	expectedLen := stateVectorLen
+	if extended {
+		expectedLen = stateVectorExtendedLen
+	}
+	if len(raw) < expectedLen {
+		err = fmt.Errorf("response contains %v values, expected %v", len(raw), expectedLen)
+		return
+	}
+
+	icao24, ok := raw[stateIdxIcao24].(string)
+	if !ok {
+		err = fmt.Errorf("invalid icao24 value: %v", raw[stateIdxIcao24])
+		return
+	}
+
+	var callsign *string
+	if rawCallsign, ok := raw[stateIdxCallsign].(string); ok {
+		trimmed := strings.TrimSpace(rawCallsign)
+		callsign = &trimmed
+	}
+
+	originCountry, _ := raw[stateIdxOriginCountry].(string)
+
+	var timePosition *time.Time
+	if raw[stateIdxTimePosition] != nil {
+		var t int64
+		t, err = numberToInt(raw[stateIdxTimePosition])
+		if err != nil {
+			err = fmt.Errorf("invalid time_position value: %w", err)
+			return
+		}
+		parsed := time.Unix(t, 0)
+		timePosition = &parsed
+	}
+
+	lastContact, err := numberToInt(raw[stateIdxLastContact])
+	if err != nil {
+		err = fmt.Errorf("invalid last_contact value: %w", err)
+		return
+	}
+
+	longitude := floatPtr(raw[stateIdxLongitude])
+	latitude := floatPtr(raw[stateIdxLatitude])
+	baroAltitude := floatPtr(raw[stateIdxBaroAltitude])
+
+	onGround, ok := raw[stateIdxOnGround].(bool)
+	if !ok {
+		err = fmt.Errorf("invalid on_ground value: %v", raw[stateIdxOnGround])
+		return
+	}
+
+	velocity := floatPtr(raw[stateIdxVelocity])
+	trueTrack := floatPtr(raw[stateIdxTrueTrack])
+	verticalRate := floatPtr(raw[stateIdxVerticalRate])
+
+	var sensors []int
+	if rawSensors, ok := raw[stateIdxSensors].([]interface{}); ok {
+		for _, rawSensor := range rawSensors {
+			var sensor int64
+			sensor, err = numberToInt(rawSensor)
+			if err != nil {
+				err = fmt.Errorf("invalid sensors value: %w", err)
+				return
+			}
+			sensors = append(sensors, int(sensor))
+		}
+	}
+
+	geoAltitude := floatPtr(raw[stateIdxGeoAltitude])
+
+	var squawkCode *string
+	if rawSquawk, ok := raw[stateIdxSquawk].(string); ok {
+		squawkCode = &rawSquawk
+	}
+
+	spi, _ := raw[stateIdxSpi].(bool)
+
+	rawPositionSource, err := numberToInt(raw[stateIdxPositionSource])
+	if err != nil {
+		err = fmt.Errorf("invalid position_source value: %w", err)
+		return
+	}
+
+	var category *int
+	if extended && raw[stateIdxCategory] != nil {
+		var c int64
+		c, err = numberToInt(raw[stateIdxCategory])
+		if err != nil {
+			err = fmt.Errorf("invalid category value: %w", err)
+			return
+		}
+		catInt := int(c)
+		category = &catInt
+	}
+
+	state = StateVector{
+		Icao24:         icao24,
+		Callsign:       callsign,
+		OriginCountry:  originCountry,
+		TimePosition:   timePosition,
+		LastContact:    time.Unix(lastContact, 0),
+		Longitude:      longitude,
+		Latitude:       latitude,
+		BaroAltitude:   baroAltitude,
+		OnGround:       onGround,
+		Velocity:       velocity,
+		TrueTrack:      trueTrack,
+		VerticalRate:   verticalRate,
+		Sensors:        sensors,
+		GeoAltitude:    geoAltitude,
+		SquawkCode:     squawkCode,
+		Spi:            spi,
+		PositionSource: PositionSource(rawPositionSource),
+		Category:       category,
+	}
+	return
+}
+
+// floatPtr returns a pointer to val's float64 representation, or nil if val isn't one.
+func floatPtr(val interface{}) *float64 {
+	f, ok := val.(float64)
+	if !ok {
+		return nil
+	}
+	return &f
+}
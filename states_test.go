@@ -0,0 +1,119 @@
+package opensky
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func loadStatesFixture(t *testing.T, path string) unstructuredStatesResponse {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var raw unstructuredStatesResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshalling fixture: %v", err)
+	}
+	return raw
+}
+
+func TestParseStatesResponseGolden(t *testing.T) {
+	raw := loadStatesFixture(t, "testdata/states_response.json")
+
+	response, err := parseStatesResponse(raw, false)
+	if err != nil {
+		t.Fatalf("parseStatesResponse: %v", err)
+	}
+	if !response.Time.Equal(time.Unix(1458564121, 0)) {
+		t.Fatalf("Time = %v, want %v", response.Time, time.Unix(1458564121, 0))
+	}
+	if len(response.States) != 2 {
+		t.Fatalf("got %d states, want 2", len(response.States))
+	}
+
+	first := response.States[0]
+	if first.Icao24 != "3c6444" {
+		t.Fatalf("Icao24 = %q, want 3c6444", first.Icao24)
+	}
+	if first.Callsign == nil || *first.Callsign != "DLH9LF" {
+		t.Fatalf("Callsign = %v, want DLH9LF", first.Callsign)
+	}
+	if first.Longitude == nil || *first.Longitude != 6.1546 {
+		t.Fatalf("Longitude = %v, want 6.1546", first.Longitude)
+	}
+	if first.OnGround {
+		t.Fatal("OnGround = true, want false")
+	}
+	if first.Category != nil {
+		t.Fatalf("Category = %v, want nil (not an extended response)", first.Category)
+	}
+
+	second := response.States[1]
+	if second.Icao24 != "aa1234" {
+		t.Fatalf("Icao24 = %q, want aa1234", second.Icao24)
+	}
+	if second.Callsign != nil {
+		t.Fatalf("Callsign = %v, want nil", second.Callsign)
+	}
+	if second.Longitude != nil {
+		t.Fatalf("Longitude = %v, want nil", second.Longitude)
+	}
+	if !second.OnGround {
+		t.Fatal("OnGround = false, want true")
+	}
+}
+
+func TestParseStatesResponseGoldenExtended(t *testing.T) {
+	raw := loadStatesFixture(t, "testdata/states_response_extended.json")
+
+	response, err := parseStatesResponse(raw, true)
+	if err != nil {
+		t.Fatalf("parseStatesResponse: %v", err)
+	}
+	if len(response.States) != 1 {
+		t.Fatalf("got %d states, want 1", len(response.States))
+	}
+	if category := response.States[0].Category; category == nil || *category != 3 {
+		t.Fatalf("Category = %v, want 3", category)
+	}
+}
+
+func TestApplyStateOptionsRepeatsIcao24Params(t *testing.T) {
+	q := url.Values{}
+	applyStateOptions(q, StateOptions{Icao24: []string{"abc123", "def456"}})
+
+	got := q["icao24"]
+	want := []string{"abc123", "def456"}
+	if len(got) != len(want) {
+		t.Fatalf("icao24 params = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("icao24 params = %v, want %v", got, want)
+		}
+	}
+
+	encoded := q.Encode()
+	if encoded != "icao24=abc123&icao24=def456" {
+		t.Fatalf("encoded query = %q, want repeated icao24 params", encoded)
+	}
+}
+
+func TestDecodeStateRejectsShortVector(t *testing.T) {
+	raw := []interface{}{"3c6444"}
+	if _, err := decodeState(raw, false); err == nil {
+		t.Fatal("expected error for a truncated state vector")
+	}
+}
+
+func TestDecodeStateRejectsInvalidIcao24(t *testing.T) {
+	raw := make([]interface{}, stateVectorLen)
+	raw[stateIdxIcao24] = 12345 // not a string
+	if _, err := decodeState(raw, false); err == nil {
+		t.Fatal("expected error for a non-string icao24")
+	}
+}
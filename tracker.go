@@ -0,0 +1,228 @@
+package opensky
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TrafficEventType identifies what changed about a tracked aircraft.
+type TrafficEventType int
+
+const (
+	// TrafficSeen is emitted the first time an aircraft is observed.
+	TrafficSeen TrafficEventType = iota
+	// TrafficUpdated is emitted whenever a previously seen aircraft's state changes.
+	TrafficUpdated
+	// TrafficLost is emitted once an aircraft hasn't been seen for longer than the
+	// tracker's TTL.
+	TrafficLost
+)
+
+// LatLon is a plain geographic position, used to retain an aircraft's last known
+// location across polls that report a nil latitude/longitude.
+type LatLon struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// TrafficInfo mirrors StateVector with a few fields derived from the polling history.
+type TrafficInfo struct {
+	StateVector
+	// LastSeen is the time of the most recent poll in which this aircraft was reported.
+	LastSeen time.Time
+	// LastPosition holds the most recent non-nil latitude/longitude, even across polls
+	// where the API reports a nil position.
+	LastPosition *LatLon
+}
+
+// TrafficEvent is delivered to Tracker subscribers whenever an aircraft is seen, updated,
+// or lost.
+type TrafficEvent struct {
+	Type   TrafficEventType
+	Icao24 string
+	Info   TrafficInfo
+}
+
+// TrackerOptions configures a Tracker.
+type TrackerOptions struct {
+	// Interval is how often the tracker polls /states/all. Defaults to 10 seconds.
+	Interval time.Duration
+	// TTL is how long an aircraft may go unseen before it is evicted and a TrafficLost
+	// event is emitted. Defaults to 5 minutes.
+	TTL time.Duration
+	// BoundingBox, if set, restricts polling to a geographic region.
+	BoundingBox *BBox
+	// Icao24, if set, restricts polling to the given transponder addresses.
+	Icao24 []string
+}
+
+const (
+	defaultTrackerInterval = 10 * time.Second
+	defaultTrackerTTL      = 5 * time.Minute
+)
+
+// Tracker polls /states/all on an interval and maintains a live, in-memory traffic table
+// keyed by icao24 address. It is the building block for radar-style dashboards that need
+// more than a one-shot snapshot of the airspace.
+type Tracker struct {
+	client *Client
+	opts   TrackerOptions
+
+	mu          sync.Mutex
+	traffic     map[string]*TrafficInfo
+	subscribers []chan TrafficEvent
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTracker creates a Tracker that will poll client. Call Start to begin polling.
+func NewTracker(client *Client, opts TrackerOptions) *Tracker {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultTrackerInterval
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTrackerTTL
+	}
+	return &Tracker{
+		client:  client,
+		opts:    opts,
+		traffic: make(map[string]*TrafficInfo),
+	}
+}
+
+// Start launches the polling goroutine. It returns once the first poll has completed, and
+// returns the first poll's error without starting the goroutine if it fails.
+// Call Stop to shut the tracker down; Stop is a no-op if Start never succeeded.
+func (t *Tracker) Start(ctx context.Context) error {
+	pollCtx, cancel := context.WithCancel(ctx)
+
+	if err := t.poll(pollCtx); err != nil {
+		cancel()
+		return err
+	}
+
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	go t.run(pollCtx)
+	return nil
+}
+
+// Stop halts polling and closes all subscriber channels. It is a no-op if Start was never
+// called or never succeeded.
+func (t *Tracker) Stop() {
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+	<-t.done
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, sub := range t.subscribers {
+		close(sub)
+	}
+	t.subscribers = nil
+}
+
+// Subscribe returns a channel that receives TrafficEvent updates as they happen. The channel
+// is closed when the tracker is stopped.
+func (t *Tracker) Subscribe() <-chan TrafficEvent {
+	ch := make(chan TrafficEvent, 32)
+	t.mu.Lock()
+	t.subscribers = append(t.subscribers, ch)
+	t.mu.Unlock()
+	return ch
+}
+
+// Snapshot returns the current traffic table. It's a convenience for consumers that want to
+// poll instead of subscribing to events.
+func (t *Tracker) Snapshot() []TrafficInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	infos := make([]TrafficInfo, 0, len(t.traffic))
+	for _, info := range t.traffic {
+		infos = append(infos, *info)
+	}
+	return infos
+}
+
+func (t *Tracker) run(ctx context.Context) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = t.poll(ctx)
+		}
+	}
+}
+
+// poll fetches the current states, merges them into the traffic table, evicts stale entries,
+// and emits the resulting TrafficEvents to all subscribers.
+func (t *Tracker) poll(ctx context.Context) error {
+	response, err := t.client.GetStates(ctx, StateOptions{
+		BoundingBox: t.opts.BoundingBox,
+		Icao24:      t.opts.Icao24,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := response.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var events []TrafficEvent
+
+	t.mu.Lock()
+	seen := make(map[string]bool, len(response.States))
+	for _, state := range response.States {
+		seen[state.Icao24] = true
+
+		existing, ok := t.traffic[state.Icao24]
+		info := TrafficInfo{StateVector: state, LastSeen: now}
+		if state.Latitude != nil && state.Longitude != nil {
+			info.LastPosition = &LatLon{Latitude: *state.Latitude, Longitude: *state.Longitude}
+		} else if ok {
+			info.LastPosition = existing.LastPosition
+		}
+
+		t.traffic[state.Icao24] = &info
+		if !ok {
+			events = append(events, TrafficEvent{Type: TrafficSeen, Icao24: state.Icao24, Info: info})
+		} else {
+			events = append(events, TrafficEvent{Type: TrafficUpdated, Icao24: state.Icao24, Info: info})
+		}
+	}
+
+	for icao24, info := range t.traffic {
+		if seen[icao24] {
+			continue
+		}
+		if now.Sub(info.LastSeen) > t.opts.TTL {
+			delete(t.traffic, icao24)
+			events = append(events, TrafficEvent{Type: TrafficLost, Icao24: icao24, Info: *info})
+		}
+	}
+	subscribers := append([]chan TrafficEvent(nil), t.subscribers...)
+	t.mu.Unlock()
+
+	for _, event := range events {
+		for _, sub := range subscribers {
+			select {
+			case sub <- event:
+			default:
+			}
+		}
+	}
+	return nil
+}
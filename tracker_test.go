@@ -0,0 +1,96 @@
+package opensky
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestTrackerStartFailureStopIsNoOp reproduces the deadlock a failed first poll used to cause:
+// Stop must not block forever on a goroutine that Start never launched.
+func TestTrackerStartFailureStopIsNoOp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"boom"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient("", "", WithBaseURL(server.URL))
+	tracker := NewTracker(client, TrackerOptions{Interval: time.Millisecond})
+
+	if err := tracker.Start(context.Background()); err == nil {
+		t.Fatal("expected Start to return the first poll's error")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tracker.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked forever after a failed Start")
+	}
+}
+
+// statesServer serves a fixed sequence of /states/all responses, one per call, repeating the
+// last one once exhausted.
+func statesServer(t *testing.T, responses ...unstructuredStatesResponse) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	call := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		idx := call
+		if idx >= len(responses) {
+			idx = len(responses) - 1
+		}
+		call++
+		mu.Unlock()
+
+		if err := json.NewEncoder(w).Encode(responses[idx]); err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func TestTrackerEmitsSeenUpdatedLost(t *testing.T) {
+	aircraft := []interface{}{"abc123", nil, "FR", nil, float64(1000), 1.0, 2.0, float64(1000), false, nil, nil, nil, nil, nil, nil, false, float64(0)}
+	aircraftMoved := []interface{}{"abc123", nil, "FR", nil, float64(1001), 3.0, 4.0, float64(1000), false, nil, nil, nil, nil, nil, nil, false, float64(0)}
+
+	server := statesServer(t,
+		unstructuredStatesResponse{Time: 1000, States: [][]interface{}{aircraft}},
+		unstructuredStatesResponse{Time: 1001, States: [][]interface{}{aircraftMoved}},
+		unstructuredStatesResponse{Time: 1002, States: [][]interface{}{}},
+	)
+	defer server.Close()
+
+	client := NewClient("", "", WithBaseURL(server.URL))
+	tracker := NewTracker(client, TrackerOptions{Interval: 5 * time.Millisecond, TTL: time.Nanosecond})
+	events := tracker.Subscribe()
+
+	if err := tracker.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer tracker.Stop()
+
+	wantTypes := []TrafficEventType{TrafficSeen, TrafficUpdated, TrafficLost}
+	for i, want := range wantTypes {
+		select {
+		case event := <-events:
+			if event.Type != want {
+				t.Fatalf("event %d: got type %v, want %v", i, event.Type, want)
+			}
+			if event.Icao24 != "abc123" {
+				t.Fatalf("event %d: got icao24 %q, want abc123", i, event.Icao24)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for event %d (%v)", i, want)
+		}
+	}
+}